@@ -0,0 +1,208 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package errors is devlake's error type, carrying enough structure for
+// server/api to render a consistent {code, message, userMessage, causes,
+// stack} response envelope without every call site building one by hand.
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// stacktraceEnabled mirrors the ENABLE_STACKTRACE config flag. Capturing a
+// stack on every New/Wrap is not free, so it only happens once this is on.
+var stacktraceEnabled atomic.Bool
+
+// EnableStacktrace toggles whether New/Wrap capture a stacktrace. It should
+// be called once at startup with the resolved ENABLE_STACKTRACE value.
+func EnableStacktrace(enabled bool) {
+	stacktraceEnabled.Store(enabled)
+}
+
+// Error is devlake's error type. It behaves like a normal `error` (and
+// wraps one, most of the time) but carries the extra fields server/api's
+// response envelope needs.
+type Error interface {
+	error
+	// Code is a machine-readable identifier, e.g. "MIGRATION_REQUIRED".
+	Code() string
+	// UserMessage is safe to surface directly in config-ui. It defaults to
+	// the internal message when no WithUserMessage was given, since most
+	// errors raised with New/Wrap are already written with users in mind.
+	UserMessage() string
+	// Causes lists the wrapped cause chain, outermost cause first.
+	Causes() []string
+	// Stack is the stacktrace captured at the New/Wrap site, or "" when
+	// ENABLE_STACKTRACE is off.
+	Stack() string
+	// HttpStatus is the status code this error should be reported as.
+	HttpStatus() int
+}
+
+type errorImpl struct {
+	code        string
+	message     string
+	userMessage string
+	cause       error
+	stack       string
+	httpStatus  int
+}
+
+func (e *errorImpl) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %s", e.message, e.cause.Error())
+	}
+	return e.message
+}
+
+func (e *errorImpl) Code() string { return e.code }
+
+func (e *errorImpl) UserMessage() string {
+	if e.userMessage != "" {
+		return e.userMessage
+	}
+	return e.message
+}
+
+func (e *errorImpl) Causes() []string {
+	var causes []string
+	for cause := e.cause; cause != nil; {
+		causes = append(causes, cause.Error())
+		inner, ok := cause.(*errorImpl)
+		if !ok {
+			break
+		}
+		cause = inner.cause
+	}
+	return causes
+}
+
+func (e *errorImpl) Stack() string   { return e.stack }
+func (e *errorImpl) HttpStatus() int { return e.httpStatus }
+
+// Builder constructs Errors that share an HTTP status, code and user
+// message, e.g. the package-level BadInput/NotFound/Unauthorized builders
+// below, or a one-off from HttpStatus(status).
+type Builder struct {
+	httpStatus  int
+	code        string
+	userMessage string
+}
+
+// Default builds plain 500s with no preset code, the equivalent of a bare
+// `errors.New`/`fmt.Errorf` elsewhere in the codebase.
+var Default = Builder{httpStatus: http.StatusInternalServerError}
+
+// BadInput, NotFound and Unauthorized are the common HTTP-status builders
+// used throughout server/api; reach for HttpStatus(status) for anything
+// else.
+var (
+	BadInput     = Builder{httpStatus: http.StatusBadRequest, code: "BAD_INPUT"}
+	NotFound     = Builder{httpStatus: http.StatusNotFound, code: "NOT_FOUND"}
+	Unauthorized = Builder{httpStatus: http.StatusUnauthorized, code: "UNAUTHORIZED"}
+)
+
+// HttpStatus starts a Builder for an arbitrary HTTP status, for the cases
+// that don't fit BadInput/NotFound/Unauthorized (e.g. 429, 412).
+func HttpStatus(status int) Builder {
+	return Builder{httpStatus: status}
+}
+
+// WithCode sets the machine-readable code new Errors from this Builder
+// will carry, e.g. "MIGRATION_REQUIRED" or "PLUGIN_NOT_FOUND".
+func (b Builder) WithCode(code string) Builder {
+	b.code = code
+	return b
+}
+
+// WithUserMessage sets the config-ui-safe message new Errors from this
+// Builder will carry, so internal detail (which may mention table names,
+// plugin internals, etc.) doesn't leak to the end user by default.
+func (b Builder) WithUserMessage(userMessage string) Builder {
+	b.userMessage = userMessage
+	return b
+}
+
+// New builds a new Error with no wrapped cause.
+func (b Builder) New(message string) Error {
+	return b.build(message, nil)
+}
+
+// Wrap builds a new Error wrapping cause, which may itself be an Error -
+// in which case its Causes() are threaded through.
+func (b Builder) Wrap(cause error, message string) Error {
+	return b.build(message, cause)
+}
+
+func (b Builder) build(message string, cause error) Error {
+	e := &errorImpl{
+		code:        b.code,
+		message:     message,
+		userMessage: b.userMessage,
+		cause:       cause,
+		httpStatus:  b.httpStatus,
+	}
+	if stacktraceEnabled.Load() {
+		e.stack = string(debug.Stack())
+	}
+	return e
+}
+
+// CodeOf returns err's code, or "" for a nil error.
+func CodeOf(err Error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Code()
+}
+
+// UserMessageOf returns err's user-safe message, or "" for a nil error.
+func UserMessageOf(err Error) string {
+	if err == nil {
+		return ""
+	}
+	return err.UserMessage()
+}
+
+// CausesOf returns err's wrapped cause chain, or nil for a nil error.
+func CausesOf(err Error) []string {
+	if err == nil {
+		return nil
+	}
+	return err.Causes()
+}
+
+// StackOf returns err's captured stacktrace, or "" for a nil error or one
+// captured with ENABLE_STACKTRACE off.
+func StackOf(err Error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Stack()
+}
+
+// HttpStatusOf returns err's HTTP status, or 0 for a nil error.
+func HttpStatusOf(err Error) int {
+	if err == nil {
+		return 0
+	}
+	return err.HttpStatus()
+}
@@ -0,0 +1,112 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestErrorCauses(t *testing.T) {
+	t.Run("no cause yields no causes", func(t *testing.T) {
+		err := Default.New("top level")
+		if got := err.Causes(); got != nil {
+			t.Errorf("Causes() = %v, want nil", got)
+		}
+	})
+
+	t.Run("wrapping a plain error yields a single cause", func(t *testing.T) {
+		err := Default.Wrap(errors.New("boom"), "wrapper")
+		want := []string{"boom"}
+		if got := err.Causes(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Causes() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("wrapping an Error threads the whole chain, outermost first", func(t *testing.T) {
+		inner := Default.New("innermost")
+		middle := Default.Wrap(inner, "middle")
+		outer := Default.Wrap(middle, "outer")
+
+		want := []string{"middle: innermost", "innermost"}
+		if got := outer.Causes(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Causes() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestErrorEnvelopeShaping(t *testing.T) {
+	EnableStacktrace(false)
+	defer EnableStacktrace(false)
+
+	err := BadInput.WithCode("BAD_THING").WithUserMessage("please fix your input").New("invalid field foo")
+
+	if got := CodeOf(err); got != "BAD_THING" {
+		t.Errorf("CodeOf() = %q, want %q", got, "BAD_THING")
+	}
+	if got := UserMessageOf(err); got != "please fix your input" {
+		t.Errorf("UserMessageOf() = %q, want %q", got, "please fix your input")
+	}
+	if got := HttpStatusOf(err); got != http.StatusBadRequest {
+		t.Errorf("HttpStatusOf() = %d, want %d", got, http.StatusBadRequest)
+	}
+	if got := err.Error(); got != "invalid field foo" {
+		t.Errorf("Error() = %q, want %q", got, "invalid field foo")
+	}
+}
+
+func TestUserMessageDefaultsToMessage(t *testing.T) {
+	err := Default.New("internal detail")
+	if got := err.UserMessage(); got != "internal detail" {
+		t.Errorf("UserMessage() = %q, want it to default to the internal message %q", got, "internal detail")
+	}
+}
+
+func TestStacktraceGatedByEnableStacktrace(t *testing.T) {
+	EnableStacktrace(false)
+	if got := Default.New("no stack").Stack(); got != "" {
+		t.Errorf("Stack() = %q, want empty when stacktraces are disabled", got)
+	}
+
+	EnableStacktrace(true)
+	defer EnableStacktrace(false)
+	if got := Default.New("with stack").Stack(); got == "" {
+		t.Error("Stack() = \"\", want a captured stacktrace when stacktraces are enabled")
+	}
+}
+
+func TestNilErrorHelpers(t *testing.T) {
+	var err Error
+	if got := CodeOf(err); got != "" {
+		t.Errorf("CodeOf(nil) = %q, want \"\"", got)
+	}
+	if got := UserMessageOf(err); got != "" {
+		t.Errorf("UserMessageOf(nil) = %q, want \"\"", got)
+	}
+	if got := CausesOf(err); got != nil {
+		t.Errorf("CausesOf(nil) = %v, want nil", got)
+	}
+	if got := StackOf(err); got != "" {
+		t.Errorf("StackOf(nil) = %q, want \"\"", got)
+	}
+	if got := HttpStatusOf(err); got != 0 {
+		t.Errorf("HttpStatusOf(nil) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"net/http"
+)
+
+// PluginWebhookReceiver lets a plugin accept signed webhook deliveries
+// through the shared server/api/webhooks endpoint instead of mounting its
+// own route and reimplementing signature verification and replay
+// protection.
+type PluginWebhookReceiver interface {
+	// VerifySignature checks rawBody against whatever signature header the
+	// provider sent, using secret - the requesting connection's decrypted
+	// shared secret - to compute the expected value. Implementations that
+	// don't need provider-specific handling can delegate to
+	// webhooks.VerifyBuiltinSignature.
+	VerifySignature(headers http.Header, rawBody []byte, secret string) error
+	// Handle processes a verified, non-replayed delivery.
+	Handle(ctx context.Context, rawBody []byte) error
+}
@@ -38,8 +38,10 @@ import (
 	"github.com/apache/incubator-devlake/impls/logruslog"
 	_ "github.com/apache/incubator-devlake/server/api/docs"
 	"github.com/apache/incubator-devlake/server/api/ping"
+	"github.com/apache/incubator-devlake/server/api/remote"
 	"github.com/apache/incubator-devlake/server/api/shared"
 	"github.com/apache/incubator-devlake/server/api/version"
+	"github.com/apache/incubator-devlake/server/api/webhooks"
 	"github.com/apache/incubator-devlake/server/services"
 )
 
@@ -56,6 +58,13 @@ func Init() {
 	// Initialize services
 	services.Init()
 	basicRes = services.GetBasicRes()
+
+	// Initialize the runtime (out-of-process) plugin registry and start
+	// sweeping registrations whose heartbeat has gone stale.
+	if err := remote.Init(basicRes); err != nil {
+		panic(err)
+	}
+	remote.StartLivenessProbe(basicRes.GetContext())
 }
 
 // @title  DevLake Swagger API
@@ -81,17 +90,60 @@ func CreateApiServer() *gin.Engine {
 	router.GET("/health", ping.Get)
 	router.GET("/version", version.Get)
 
-	// Api keys
-	router.Use(RestAuthentication(router, basicRes))
-	router.Use(OAuth2ProxyAuthentication(basicRes))
+	// Authentication, one middleware per mode configured in
+	// AUTHENTICATION_MODE (comma-separated, e.g. "rest,saml"). Defaults to
+	// "rest,oauth2proxy" to preserve pre-existing behavior when unset.
+	for _, mode := range authenticationModes(basicRes) {
+		switch mode {
+		case "rest":
+			router.Use(RestAuthentication(router, basicRes))
+		case "oauth2proxy":
+			router.Use(OAuth2ProxyAuthentication(basicRes))
+		case "saml":
+			router.Use(SAMLAuthentication(basicRes))
+		default:
+			logruslog.Global.Warnf("unknown AUTHENTICATION_MODE %q, ignoring", mode)
+		}
+	}
 
 	return router
 }
 
+// authenticationModes parses the AUTHENTICATION_MODE config into the list
+// of authentication middlewares to chain, defaulting to the historical
+// rest+oauth2proxy combination so existing deployments are unaffected.
+func authenticationModes(basicRes context.BasicRes) []string {
+	return parseAuthenticationModes(basicRes.GetConfig("AUTHENTICATION_MODE"))
+}
+
+// parseAuthenticationModes is the config-free half of authenticationModes,
+// split out so the parsing itself can be unit tested without a BasicRes.
+func parseAuthenticationModes(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return []string{"rest", "oauth2proxy"}
+	}
+	modes := make([]string, 0, 3)
+	for _, mode := range strings.Split(raw, ",") {
+		if mode = strings.TrimSpace(mode); mode != "" {
+			modes = append(modes, mode)
+		}
+	}
+	return modes
+}
+
 func SetupApiServer(router *gin.Engine) {
 	// Set gin mode
 	gin.SetMode(basicRes.GetConfig("MODE"))
 
+	// Stacktraces are expensive to capture and can leak internals, so
+	// they're opt-in: errors.New/Wrap only captures one once this is set.
+	errors.EnableStacktrace(basicRes.GetConfig("ENABLE_STACKTRACE") == "true")
+
+	// Turn panics into the same structured error envelope as a handled
+	// error, instead of gin's default plaintext 500.
+	router.Use(panicRecovery())
+
 	// Endpoint to proceed database migration
 	router.GET("/proceed-db-migration", func(ctx *gin.Context) {
 		// Check if migration requires confirmation
@@ -119,7 +171,10 @@ func SetupApiServer(router *gin.Engine) {
 		// Return error response
 		shared.ApiOutputError(
 			ctx,
-			errors.HttpStatus(http.StatusPreconditionRequired).New(DB_MIGRATION_REQUIRED),
+			errors.HttpStatus(http.StatusPreconditionRequired).
+				WithCode("MIGRATION_REQUIRED").
+				WithUserMessage("DevLake needs a database migration before it can serve requests. Visit the migration page to proceed.").
+				New(DB_MIGRATION_REQUIRED),
 		)
 		ctx.Abort()
 	})
@@ -127,6 +182,7 @@ func SetupApiServer(router *gin.Engine) {
 	// Add swagger handlers
 	router.GET("/swagger/*any", modifyBasePath, ginSwagger.WrapHandler(swaggerFiles.Handler))
 	registerExtraOpenApiSpecs(router)
+	registerAggregatedOpenApiSpec(router)
 
 	// Add debug logging for endpoints
 	gin.DebugPrintRouteFunc = func(httpMethod, absolutePath, handlerName string, nuHandlers int) {
@@ -149,6 +205,21 @@ func SetupApiServer(router *gin.Engine) {
 		MaxAge: 120 * time.Hour,
 	}))
 
+	// Allow out-of-process plugins to register themselves and expose a
+	// default connection CRUD API, ahead of the compiled-in plugin routes.
+	remote.RegisterRoutes(router)
+
+	// Guard against abusive or oversized requests before they reach the
+	// handlers registered below; routes registered above are not guarded.
+	router.Use(MaxRequestBodyMiddleware(basicRes))
+	router.Use(RateLimitMiddleware(basicRes))
+	router.GET("/metrics/ratelimit", getRateLimitMetrics)
+
+	// Let plugins receive signed webhooks (GitHub/GitLab/Bitbucket/generic
+	// HMAC) without each one reimplementing verification and replay
+	// protection.
+	webhooks.RegisterRoutes(router, basicRes)
+
 	// Register API endpoints
 	RegisterRouter(router, basicRes)
 }
@@ -172,31 +243,85 @@ func RunApiServer(router *gin.Engine) {
 	}
 }
 
-func registerExtraOpenApiSpecs(router *gin.Engine) {
-	for name, pluginMeta := range plugin.AllPlugins() {
-		if pluginOpenApiSpec, ok := pluginMeta.(plugin.PluginOpenApiSpec); ok {
-			spec := &swag.Spec{
-				InfoInstanceName: name,
-				SwaggerTemplate:  pluginOpenApiSpec.OpenApiSpec(),
+// panicRecovery converts a panic anywhere downstream into the same
+// {code, message, userMessage, causes, stack} envelope as a handled error,
+// so clients never see gin's default plaintext 500 page.
+func panicRecovery() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := r.(error)
+				if !ok {
+					err = fmt.Errorf("%v", r)
+				}
+				shared.ApiOutputError(ctx, errors.HttpStatus(http.StatusInternalServerError).
+					WithCode("INTERNAL_ERROR").
+					WithUserMessage("An unexpected error occurred, please check the server logs.").
+					Wrap(err, "panic recovered"))
+				ctx.Abort()
 			}
-			swag.Register(name, spec)
-			router.GET(
-				fmt.Sprintf("/plugins/swagger/%s/*any", name),
-				ginSwagger.CustomWrapHandler(
-					&ginSwagger.Config{
-						URL:                      "doc.json",
-						DocExpansion:             "list",
-						InstanceName:             name,
-						Title:                    fmt.Sprintf("%s API", name),
-						DefaultModelsExpandDepth: 1,
-						DeepLinking:              true,
-						PersistAuthorization:     false,
-					},
-					swaggerFiles.Handler,
-				),
-			)
+		}()
+		ctx.Next()
+	}
+}
+
+// registerExtraOpenApiSpecs mounts a single dynamic swagger UI route that
+// resolves a plugin's OpenAPI spec per request - compiled-in
+// (plugin.AllPlugins()) or runtime-registered (remote.AllPlugins()) - rather
+// than registering one static gin route per plugin at startup. That's what
+// lets a plugin that calls POST /remote-plugins/register after boot get a
+// working spec route immediately: gin can't add routes to a tree that's
+// already serving traffic, but swag.Register (just a map insert) and
+// ginSwagger's handler construction are both safe to do per request.
+//
+// This lives under /plugin-swagger rather than /plugins/swagger: the
+// remote package's connection CRUD group claims /plugins/:pluginName/...,
+// and a static "swagger" path segment can't be a sibling of that
+// :pluginName wildcard without gin panicking at startup (the same
+// static-vs-wildcard conflict documented on remote.RegisterRoutes).
+func registerExtraOpenApiSpecs(router *gin.Engine) {
+	router.GET("/plugin-swagger/:name/*any", servePluginSwagger)
+}
+
+func servePluginSwagger(ctx *gin.Context) {
+	name := ctx.Param("name")
+	template, err := pluginOpenApiSpecFor(name)
+	if err != nil {
+		shared.ApiOutputError(ctx, err)
+		return
+	}
+	swag.Register(name, &swag.Spec{InfoInstanceName: name, SwaggerTemplate: template})
+	ginSwagger.CustomWrapHandler(
+		&ginSwagger.Config{
+			URL:                      "doc.json",
+			DocExpansion:             "list",
+			InstanceName:             name,
+			Title:                    fmt.Sprintf("%s API", name),
+			DefaultModelsExpandDepth: 1,
+			DeepLinking:              true,
+			PersistAuthorization:     false,
+		},
+		swaggerFiles.Handler,
+	)(ctx)
+}
+
+// pluginOpenApiSpecFor resolves a plugin's OpenAPI spec template by name,
+// checking compiled-in plugins before falling back to runtime-registered
+// remote plugins.
+func pluginOpenApiSpecFor(name string) (string, errors.Error) {
+	if pluginMeta, ok := plugin.AllPlugins()[name]; ok {
+		if s, ok := pluginMeta.(plugin.PluginOpenApiSpec); ok {
+			return s.OpenApiSpec(), nil
+		}
+		return "", errors.NotFound.New("plugin does not expose an OpenAPI spec: " + name)
+	}
+	if remotePlugin, ok := remote.Get(name); ok {
+		if remotePlugin.OpenApiSpec == "" {
+			return "", errors.NotFound.New("plugin does not expose an OpenAPI spec: " + name)
 		}
+		return remotePlugin.OpenApiSpec, nil
 	}
+	return "", errors.NotFound.New("plugin not found: " + name)
 }
 
 type bodyTamper struct {
@@ -0,0 +1,46 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAuthenticationModes(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{name: "unset defaults to rest+oauth2proxy", raw: "", want: []string{"rest", "oauth2proxy"}},
+		{name: "whitespace-only defaults to rest+oauth2proxy", raw: "   ", want: []string{"rest", "oauth2proxy"}},
+		{name: "single mode", raw: "saml", want: []string{"saml"}},
+		{name: "multiple modes trimmed", raw: "rest, saml , oauth2proxy", want: []string{"rest", "saml", "oauth2proxy"}},
+		{name: "empty entries dropped", raw: "rest,,saml", want: []string{"rest", "saml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAuthenticationModes(tt.raw)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAuthenticationModes(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
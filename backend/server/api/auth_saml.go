@@ -0,0 +1,363 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"github.com/gin-gonic/gin"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/apache/incubator-devlake/server/api/shared"
+)
+
+// samlSessionCookie carries the devlake session token minted after a
+// successful SAML assertion, readable by the existing RestAuthentication
+// check alongside api-key and basic-auth sessions.
+const samlSessionCookie = "devlake-saml-session"
+
+// samlNameIDAttribute is the attribute devlake looks up on the assertion to
+// identify the signed-in user, falling back to the Subject NameID itself.
+const samlNameIDAttribute = "email"
+
+// SAMLAuthentication implements a SP-initiated SAML 2.0 login flow and
+// mints a devlake session once the IdP's assertion is verified. It
+// participates in the same middleware chain as RestAuthentication and
+// OAuth2ProxyAuthentication: requests already carrying a valid SAML
+// session cookie pass through, the three SAML routes are always open so
+// the flow can complete, and everything else is left for the next
+// authentication middleware to judge.
+func SAMLAuthentication(basicRes context.BasicRes) gin.HandlerFunc {
+	sp, err := newSAMLServiceProvider(basicRes)
+	if err != nil {
+		// Misconfiguration shouldn't crash devlake if SAML isn't the mode
+		// in use; log and fall through to a no-op middleware instead.
+		logruslog.Global.Error(err, "failed to initialize SAML service provider")
+		return func(ctx *gin.Context) {}
+	}
+
+	return func(ctx *gin.Context) {
+		switch ctx.Request.URL.Path {
+		case "/auth/saml/metadata":
+			serveSAMLMetadata(ctx, sp)
+			ctx.Abort()
+			return
+		case "/auth/saml/login":
+			redirectToIdP(ctx, sp)
+			ctx.Abort()
+			return
+		case "/auth/saml/acs":
+			consumeSAMLResponse(ctx, sp)
+			ctx.Abort()
+			return
+		}
+
+		if token, err := ctx.Cookie(samlSessionCookie); err == nil && token != "" {
+			if nameID, ok := lookupSAMLSession(token); ok {
+				ctx.Set("userSession", nameID)
+				ctx.Set("authenticated", true)
+			}
+		}
+	}
+}
+
+func newSAMLServiceProvider(basicRes context.BasicRes) (*saml.ServiceProvider, errors.Error) {
+	idpMetadataURL := basicRes.GetConfig("SAML_IDP_METADATA_URL")
+	idpCertPEM := basicRes.GetConfig("SAML_IDP_CERT")
+	rootURL := basicRes.GetConfig("URL")
+
+	var idpMetadata *saml.EntityDescriptor
+	var err error
+	switch {
+	case idpMetadataURL != "":
+		idpMetadata, err = samlsp.FetchMetadata(basicRes.GetContext(), http.DefaultClient, mustParseURL(idpMetadataURL))
+	case idpCertPEM != "":
+		idpMetadata, err = idpMetadataFromCert(basicRes, idpCertPEM)
+	default:
+		return nil, errors.BadInput.New("either SAML_IDP_METADATA_URL or SAML_IDP_CERT must be configured")
+	}
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading SAML IdP metadata")
+	}
+
+	key, cert, err := loadSPCredentials(basicRes)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading SAML SP signing credentials")
+	}
+
+	base := mustParseURL(rootURL)
+	sp := &saml.ServiceProvider{
+		EntityID:    base.String(),
+		AcsURL:      *joinURL(base, "/auth/saml/acs"),
+		MetadataURL: *joinURL(base, "/auth/saml/metadata"),
+		IDPMetadata: idpMetadata,
+		Key:         key,
+		Certificate: cert,
+	}
+	return sp, nil
+}
+
+// loadSPCredentials loads the SP's own signing key/cert, used to sign
+// outgoing AuthnRequests - without these, MakeAuthenticationRequest's
+// request is unsigned and some IdPs will reject it outright.
+func loadSPCredentials(basicRes context.BasicRes) (*rsa.PrivateKey, *x509.Certificate, error) {
+	keyPEM := basicRes.GetConfig("SAML_SP_KEY_PEM")
+	certPEM := basicRes.GetConfig("SAML_SP_CERT_PEM")
+	if keyPEM == "" || certPEM == "" {
+		return nil, nil, errors.BadInput.New("SAML_SP_KEY_PEM and SAML_SP_CERT_PEM must both be configured to sign AuthnRequests")
+	}
+
+	keyBlock, _ := pem.Decode([]byte(keyPEM))
+	if keyBlock == nil {
+		return nil, nil, errors.Default.New("SAML_SP_KEY_PEM is not valid PEM")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		parsedKey, pkcs8Err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+		if pkcs8Err != nil {
+			return nil, nil, errors.Default.Wrap(err, "error parsing SAML_SP_KEY_PEM")
+		}
+		rsaKey, ok := parsedKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, errors.Default.New("SAML_SP_KEY_PEM must be an RSA private key")
+		}
+		key = rsaKey
+	}
+
+	certBlock, _ := pem.Decode([]byte(certPEM))
+	if certBlock == nil {
+		return nil, nil, errors.Default.New("SAML_SP_CERT_PEM is not valid PEM")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, errors.Default.Wrap(err, "error parsing SAML_SP_CERT_PEM")
+	}
+
+	return key, cert, nil
+}
+
+func serveSAMLMetadata(ctx *gin.Context, sp *saml.ServiceProvider) {
+	metadata := sp.Metadata()
+	ctx.XML(http.StatusOK, metadata)
+}
+
+func redirectToIdP(ctx *gin.Context, sp *saml.ServiceProvider) {
+	authReq, err := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		shared.ApiOutputError(ctx, errors.Default.Wrap(err, "error building SAML AuthnRequest"))
+		return
+	}
+	// ParseResponse below validates the assertion's InResponseTo against
+	// this ID, so it has to be remembered here and not just discarded -
+	// otherwise every SP-initiated response is rejected as unsolicited.
+	rememberAuthnRequestID(authReq.ID)
+	redirectURL, err := authReq.Redirect("", sp)
+	if err != nil {
+		shared.ApiOutputError(ctx, errors.Default.Wrap(err, "error signing SAML AuthnRequest"))
+		return
+	}
+	ctx.Redirect(http.StatusFound, redirectURL.String())
+}
+
+func consumeSAMLResponse(ctx *gin.Context, sp *saml.ServiceProvider) {
+	if err := ctx.Request.ParseForm(); err != nil {
+		shared.ApiOutputError(ctx, errors.BadInput.Wrap(err, "error parsing SAML response form"))
+		return
+	}
+	assertion, err := sp.ParseResponse(ctx.Request, possibleRequestIDs())
+	if err != nil {
+		shared.ApiOutputError(ctx, errors.Unauthorized.Wrap(err, "error verifying SAML response"))
+		return
+	}
+
+	nameID := lookupAttribute(assertion, samlNameIDAttribute)
+	if nameID == "" && assertion.Subject != nil && assertion.Subject.NameID != nil {
+		nameID = assertion.Subject.NameID.Value
+	}
+	if nameID == "" {
+		shared.ApiOutputError(ctx, errors.Unauthorized.New("SAML assertion did not carry a usable identity"))
+		return
+	}
+
+	token, tokenErr := mintSessionToken(nameID)
+	if tokenErr != nil {
+		shared.ApiOutputError(ctx, tokenErr)
+		return
+	}
+	ctx.SetCookie(samlSessionCookie, token, 0, "/", "", false, true)
+	ctx.Redirect(http.StatusFound, "/")
+}
+
+// lookupAttribute returns the first value of the named SAML attribute.
+// IdPs are inconsistent about declaring xsi:type on attribute values, so a
+// missing/unknown type is tolerated rather than treated as an error - we
+// just log a warning and use the raw string.
+func lookupAttribute(assertion *saml.Assertion, name string) string {
+	for _, stmt := range assertion.AttributeStatements {
+		for _, attr := range stmt.Attributes {
+			if attr.Name != name && attr.FriendlyName != name {
+				continue
+			}
+			for _, v := range attr.Values {
+				if v.Type == "" {
+					logruslog.Global.Warnf("SAML attribute %s is missing an xsi:type, using raw value", name)
+				}
+				return v.Value
+			}
+		}
+	}
+	return ""
+}
+
+// idpMetadataFromCert builds a minimal IdP EntityDescriptor directly from a
+// signing cert, for IdPs that hand out a bare certificate instead of a
+// metadata document. SAML_IDP_SSO_URL is required alongside the cert since
+// there's no metadata document to read the SSO endpoint from.
+func idpMetadataFromCert(basicRes context.BasicRes, certPEM string) (*saml.EntityDescriptor, error) {
+	ssoURL := basicRes.GetConfig("SAML_IDP_SSO_URL")
+	if ssoURL == "" {
+		return nil, errors.BadInput.New("SAML_IDP_SSO_URL must be configured alongside SAML_IDP_CERT")
+	}
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.Default.New("SAML_IDP_CERT is not valid PEM")
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return nil, errors.Default.Wrap(err, "error parsing SAML_IDP_CERT")
+	}
+
+	return &saml.EntityDescriptor{
+		IDPSSODescriptors: []saml.IDPSSODescriptor{
+			{
+				SSODescriptor: saml.SSODescriptor{
+					RoleDescriptor: saml.RoleDescriptor{
+						KeyDescriptors: []saml.KeyDescriptor{
+							{
+								Use: "signing",
+								KeyInfo: saml.KeyInfo{
+									X509Data: saml.X509Data{
+										X509Certificates: []saml.X509Certificate{
+											{Data: base64.StdEncoding.EncodeToString(block.Bytes)},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				SingleSignOnServices: []saml.Endpoint{
+					{Binding: saml.HTTPRedirectBinding, Location: ssoURL},
+				},
+			},
+		},
+	}, nil
+}
+
+func mustParseURL(raw string) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func joinURL(base *url.URL, p string) *url.URL {
+	joined := *base
+	joined.Path = p
+	return &joined
+}
+
+// samlRequestTTL bounds how long a minted AuthnRequest ID is accepted as a
+// valid InResponseTo target, mirroring how long we'd expect an IdP login to
+// reasonably take.
+const samlRequestTTL = 10 * time.Minute
+
+var samlPendingRequests sync.Map // id(string) -> expiresAt time.Time
+
+// rememberAuthnRequestID records an AuthnRequest ID minted by
+// redirectToIdP, so the matching ACS response can be recognized as
+// solicited rather than rejected by crewjam/saml's InResponseTo check.
+func rememberAuthnRequestID(id string) {
+	samlPendingRequests.Store(id, time.Now().Add(samlRequestTTL))
+}
+
+// possibleRequestIDs returns every AuthnRequest ID minted within
+// samlRequestTTL, lazily evicting expired ones as it goes - the same
+// expire-on-read pattern lookupSAMLSession uses below.
+func possibleRequestIDs() []string {
+	now := time.Now()
+	var ids []string
+	samlPendingRequests.Range(func(key, value interface{}) bool {
+		if now.After(value.(time.Time)) {
+			samlPendingRequests.Delete(key)
+			return true
+		}
+		ids = append(ids, key.(string))
+		return true
+	})
+	return ids
+}
+
+// samlSessionTTL bounds how long a minted SAML session cookie is honored
+// before the user is sent back through the IdP.
+const samlSessionTTL = 24 * time.Hour
+
+type samlSession struct {
+	nameID    string
+	expiresAt time.Time
+}
+
+var samlSessions sync.Map // token(string) -> samlSession
+
+func mintSessionToken(nameID string) (string, errors.Error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.Default.Wrap(err, "error generating SAML session token")
+	}
+	token := hex.EncodeToString(raw)
+	samlSessions.Store(token, samlSession{nameID: nameID, expiresAt: time.Now().Add(samlSessionTTL)})
+	return token, nil
+}
+
+// lookupSAMLSession resolves a session cookie minted by the ACS handler,
+// so RestAuthentication can treat it the same as an api-key session.
+func lookupSAMLSession(token string) (string, bool) {
+	v, ok := samlSessions.Load(token)
+	if !ok {
+		return "", false
+	}
+	session := v.(samlSession)
+	if time.Now().After(session.expiresAt) {
+		samlSessions.Delete(token)
+		return "", false
+	}
+	return session.nameID, true
+}
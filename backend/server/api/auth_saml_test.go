@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+)
+
+func TestLookupAttribute(t *testing.T) {
+	assertion := &saml.Assertion{
+		AttributeStatements: []saml.AttributeStatement{
+			{
+				Attributes: []saml.Attribute{
+					{
+						Name:         "email",
+						FriendlyName: "Email Address",
+						Values: []saml.AttributeValue{
+							{Type: "xs:string", Value: "person@example.com"},
+						},
+					},
+					{
+						Name: "untyped",
+						Values: []saml.AttributeValue{
+							{Value: "no-xsi-type"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		attr string
+		want string
+	}{
+		{name: "match by Name", attr: "email", want: "person@example.com"},
+		{name: "match by FriendlyName", attr: "Email Address", want: "person@example.com"},
+		{name: "missing xsi:type is tolerated", attr: "untyped", want: "no-xsi-type"},
+		{name: "unknown attribute returns empty", attr: "nope", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lookupAttribute(assertion, tt.attr)
+			if got != tt.want {
+				t.Errorf("lookupAttribute(%q) = %q, want %q", tt.attr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPossibleRequestIDs(t *testing.T) {
+	samlPendingRequests = sync.Map{}
+	rememberAuthnRequestID("req-1")
+	rememberAuthnRequestID("req-2")
+
+	ids := possibleRequestIDs()
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 pending request IDs, got %d: %v", len(ids), ids)
+	}
+
+	samlPendingRequests.Store("req-expired", time.Now().Add(-time.Minute))
+	ids = possibleRequestIDs()
+	for _, id := range ids {
+		if id == "req-expired" {
+			t.Errorf("expired request ID %q should have been evicted", id)
+		}
+	}
+	if _, stillThere := samlPendingRequests.Load("req-expired"); stillThere {
+		t.Error("expired request ID should be deleted from samlPendingRequests after a read")
+	}
+}
@@ -0,0 +1,347 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/apache/incubator-devlake/server/api/shared"
+)
+
+// rateLimitRule is one "METHOD:PATTERN=N/PERIOD" clause of RATE_LIMIT_RULES,
+// e.g. "POST:/pipelines=10/min".
+type rateLimitRule struct {
+	method  string // "*" matches any method
+	pattern string // "*" matches any route
+	limit   int
+	period  time.Duration
+}
+
+func (r rateLimitRule) matches(method, route string) bool {
+	return (r.method == "*" || r.method == method) && patternMatches(r.pattern, route)
+}
+
+// patternMatches treats a trailing "/*" as a prefix wildcard (so
+// "GET:/*=600/min", the catch-all example in RATE_LIMIT_RULES' own doc,
+// actually matches every route) alongside the bare "*" any-route wildcard;
+// anything else is an exact match against ctx.FullPath().
+func patternMatches(pattern, route string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return route == prefix || strings.HasPrefix(route, prefix+"/")
+	}
+	return pattern == route
+}
+
+// parseRateLimitRules parses the RATE_LIMIT_RULES DSL: rules separated by
+// ";", each "METHOD:PATTERN=N/PERIOD" where PERIOD is "sec", "min" or
+// "hour". Malformed rules are skipped with a warning rather than failing
+// startup, since a typo in config shouldn't take devlake down.
+func parseRateLimitRules(raw string) []rateLimitRule {
+	var rules []rateLimitRule
+	for _, clause := range strings.Split(raw, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		rule, err := parseRateLimitRule(clause)
+		if err != nil {
+			logruslog.Global.Warnf("ignoring invalid RATE_LIMIT_RULES clause %q: %s", clause, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func parseRateLimitRule(clause string) (rateLimitRule, error) {
+	methodAndPattern, rate, ok := strings.Cut(clause, "=")
+	if !ok {
+		return rateLimitRule{}, fmt.Errorf("missing '='")
+	}
+	method, pattern, ok := strings.Cut(methodAndPattern, ":")
+	if !ok {
+		return rateLimitRule{}, fmt.Errorf("missing ':'")
+	}
+	countStr, periodStr, ok := strings.Cut(rate, "/")
+	if !ok {
+		return rateLimitRule{}, fmt.Errorf("missing '/'")
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil {
+		return rateLimitRule{}, fmt.Errorf("invalid count: %w", err)
+	}
+	period, err := parseRatePeriod(periodStr)
+	if err != nil {
+		return rateLimitRule{}, err
+	}
+	return rateLimitRule{method: method, pattern: pattern, limit: count, period: period}, nil
+}
+
+func parseRatePeriod(unit string) (time.Duration, error) {
+	switch unit {
+	case "sec":
+		return time.Second, nil
+	case "min":
+		return time.Minute, nil
+	case "hour":
+		return time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unknown period %q, expected sec, min or hour", unit)
+	}
+}
+
+// tokenBucket is a classic token-bucket limiter: tokens refill continuously
+// at limit/period and a request is allowed as long as at least one token
+// is available.
+type tokenBucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	limit      float64
+	refillRate float64 // tokens per second
+	updatedAt  time.Time
+	idleSince  time.Time // last time this bucket was fully refilled (= idle)
+}
+
+func newTokenBucket(rule rateLimitRule) *tokenBucket {
+	limit := float64(rule.limit)
+	now := time.Now()
+	return &tokenBucket{
+		tokens:     limit,
+		limit:      limit,
+		refillRate: limit / rule.period.Seconds(),
+		updatedAt:  now,
+		idleSince:  now,
+	}
+}
+
+// take reports whether a request may proceed, and if not, how long until
+// the next token is available.
+func (b *tokenBucket) take() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	b.updatedAt = now
+	b.tokens = minFloat(b.limit, b.tokens+elapsed*b.refillRate)
+	if b.tokens >= b.limit {
+		b.idleSince = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	missing := 1 - b.tokens
+	return false, time.Duration(missing/b.refillRate*float64(time.Second)) + time.Second
+}
+
+// idleFor reports how long this bucket has sat fully refilled, i.e. unused.
+// Refill is only applied in take, so for a bucket left short of tokens the
+// moment it became full again is worked out from its last update.
+func (b *tokenBucket) idleFor(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens >= b.limit {
+		return now.Sub(b.idleSince)
+	}
+	fullAt := b.updatedAt.Add(time.Duration((b.limit - b.tokens) / b.refillRate * float64(time.Second)))
+	if now.Before(fullAt) {
+		return 0
+	}
+	return now.Sub(fullAt)
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// rateLimiter buckets requests per (principal, matched rule, route), where
+// principal is the authenticated user (falling back to client IP for
+// unauthenticated requests) and route is the gin route pattern.
+type rateLimiter struct {
+	rules   []rateLimitRule
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newRateLimiter(rules []rateLimitRule) *rateLimiter {
+	return &rateLimiter{rules: rules, buckets: map[string]*tokenBucket{}}
+}
+
+// bucketIdleEvictAfter bounds how long a fully-refilled (i.e. unused)
+// bucket is kept around. Without this, one bucket per distinct
+// principal/route pair accumulates forever, which is itself a memory-DoS
+// vector on the exact middleware meant to guard against abuse.
+const bucketIdleEvictAfter = 10 * time.Minute
+
+// bucketSweepInterval is how often evictBuckets runs.
+const bucketSweepInterval = time.Minute
+
+// evictBuckets drops every bucket that's sat fully refilled for longer than
+// bucketIdleEvictAfter. It's safe to call concurrently with allow().
+func (l *rateLimiter) evictBuckets() {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, bucket := range l.buckets {
+		if bucket.idleFor(now) > bucketIdleEvictAfter {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// startEvictionLoop periodically sweeps idle buckets for the lifetime of
+// the process.
+func (l *rateLimiter) startEvictionLoop() {
+	ticker := time.NewTicker(bucketSweepInterval)
+	go func() {
+		for range ticker.C {
+			l.evictBuckets()
+		}
+	}()
+}
+
+func (l *rateLimiter) allow(method, route, principal string) (bool, time.Duration, *rateLimitRule) {
+	for i := range l.rules {
+		rule := l.rules[i]
+		if !rule.matches(method, route) {
+			continue
+		}
+		key := fmt.Sprintf("%s|%s:%s|%s", principal, rule.method, rule.pattern, route)
+		l.mu.Lock()
+		bucket, ok := l.buckets[key]
+		if !ok {
+			bucket = newTokenBucket(rule)
+			l.buckets[key] = bucket
+		}
+		l.mu.Unlock()
+		allowed, retryAfter := bucket.take()
+		return allowed, retryAfter, &rule
+	}
+	return true, 0, nil
+}
+
+// RateLimitMiddleware enforces RATE_LIMIT_RULES, a token bucket per
+// (authenticated user or client IP, matched rule, route pattern). gin only
+// applies it to routes registered after the Use call, so it must be
+// installed before RegisterRouter; routes registered earlier are not
+// limited.
+func RateLimitMiddleware(basicRes context.BasicRes) gin.HandlerFunc {
+	limiter := newRateLimiter(parseRateLimitRules(basicRes.GetConfig("RATE_LIMIT_RULES")))
+	if len(limiter.rules) == 0 {
+		return func(ctx *gin.Context) {}
+	}
+	limiter.startEvictionLoop()
+	return func(ctx *gin.Context) {
+		principal := rateLimitPrincipal(ctx)
+		allowed, retryAfter, rule := limiter.allow(ctx.Request.Method, ctx.FullPath(), principal)
+		if rule != nil {
+			rateLimitMetrics.record(*rule, allowed)
+		}
+		if !allowed {
+			ctx.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			shared.ApiOutputError(ctx, errors.HttpStatus(http.StatusTooManyRequests).WithCode("RATE_LIMITED").New("rate limit exceeded, please slow down"))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// rateLimitPrincipal keys the limiter on the authenticated user/api-key
+// when one is present on the context (set by the authentication
+// middlewares), falling back to client IP.
+func rateLimitPrincipal(ctx *gin.Context) string {
+	if v, ok := ctx.Get("userSession"); ok {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ctx.ClientIP()
+}
+
+// MaxRequestBodyMiddleware rejects requests whose body exceeds
+// MAX_REQUEST_BODY_BYTES before Gin unmarshals them, since blueprint and
+// pipeline POSTs are otherwise unbounded.
+func MaxRequestBodyMiddleware(basicRes context.BasicRes) gin.HandlerFunc {
+	maxBytes, err := strconv.ParseInt(basicRes.GetConfig("MAX_REQUEST_BODY_BYTES"), 10, 64)
+	if err != nil || maxBytes <= 0 {
+		return func(ctx *gin.Context) {}
+	}
+	return func(ctx *gin.Context) {
+		if ctx.Request.ContentLength > maxBytes {
+			shared.ApiOutputError(ctx, errors.HttpStatus(http.StatusRequestEntityTooLarge).WithCode("REQUEST_TOO_LARGE").New(fmt.Sprintf("request body exceeds the %d byte limit", maxBytes)))
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)
+		ctx.Next()
+	}
+}
+
+// rateLimitCounters tracks per-rule allow/reject counts for GET /metrics/ratelimit.
+type rateLimitCounters struct {
+	mu      sync.Mutex
+	allowed map[string]int64
+	limited map[string]int64
+}
+
+var rateLimitMetrics = &rateLimitCounters{allowed: map[string]int64{}, limited: map[string]int64{}}
+
+func (c *rateLimitCounters) record(rule rateLimitRule, allowed bool) {
+	key := fmt.Sprintf("%s:%s=%d/%s", rule.method, rule.pattern, rule.limit, rule.period)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if allowed {
+		c.allowed[key]++
+	} else {
+		c.limited[key]++
+	}
+}
+
+func (c *rateLimitCounters) snapshot() gin.H {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counters := make(gin.H, len(c.allowed)+len(c.limited))
+	for key, count := range c.allowed {
+		counters[key+" allowed"] = count
+	}
+	for key, count := range c.limited {
+		counters[key+" limited"] = count
+	}
+	return counters
+}
+
+func getRateLimitMetrics(ctx *gin.Context) {
+	shared.ApiOutputSuccess(ctx, rateLimitMetrics.snapshot(), http.StatusOK)
+}
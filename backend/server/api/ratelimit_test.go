@@ -0,0 +1,135 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitRules(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []rateLimitRule
+	}{
+		{
+			name: "single rule",
+			raw:  "POST:/pipelines=10/min",
+			want: []rateLimitRule{{method: "POST", pattern: "/pipelines", limit: 10, period: time.Minute}},
+		},
+		{
+			name: "multiple rules separated by semicolons",
+			raw:  "POST:/pipelines=10/min;GET:/*=600/min",
+			want: []rateLimitRule{
+				{method: "POST", pattern: "/pipelines", limit: 10, period: time.Minute},
+				{method: "GET", pattern: "/*", limit: 600, period: time.Minute},
+			},
+		},
+		{
+			name: "blank clauses and whitespace are ignored",
+			raw:  " POST:/pipelines=10/min ; ; ",
+			want: []rateLimitRule{{method: "POST", pattern: "/pipelines", limit: 10, period: time.Minute}},
+		},
+		{
+			name: "invalid clause is skipped, valid ones still parse",
+			raw:  "not-a-rule;POST:/pipelines=10/min",
+			want: []rateLimitRule{{method: "POST", pattern: "/pipelines", limit: 10, period: time.Minute}},
+		},
+		{
+			name: "unknown period is skipped",
+			raw:  "POST:/pipelines=10/fortnight",
+			want: nil,
+		},
+		{
+			name: "empty input yields no rules",
+			raw:  "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRateLimitRules(tt.raw)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseRateLimitRules(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("rule[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPatternMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		route   string
+		want    bool
+	}{
+		{name: "bare star matches anything", pattern: "*", route: "/pipelines", want: true},
+		{name: "exact match", pattern: "/pipelines", route: "/pipelines", want: true},
+		{name: "exact mismatch", pattern: "/pipelines", route: "/plugins", want: false},
+		{name: "documented /* wildcard matches any route", pattern: "/*", route: "/plugins/github/connections", want: true},
+		{name: "prefix wildcard matches the bare prefix itself", pattern: "/plugins/*", route: "/plugins", want: true},
+		{name: "prefix wildcard matches nested routes", pattern: "/plugins/*", route: "/plugins/github/connections", want: true},
+		{name: "prefix wildcard does not match an unrelated sibling prefix", pattern: "/plugins/*", route: "/pluginsomething", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := patternMatches(tt.pattern, tt.route)
+			if got != tt.want {
+				t.Errorf("patternMatches(%q, %q) = %v, want %v", tt.pattern, tt.route, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenBucketTake(t *testing.T) {
+	t.Run("allows up to the limit then blocks", func(t *testing.T) {
+		b := newTokenBucket(rateLimitRule{limit: 2, period: time.Minute})
+		if allowed, _ := b.take(); !allowed {
+			t.Fatal("first request within limit should be allowed")
+		}
+		if allowed, _ := b.take(); !allowed {
+			t.Fatal("second request within limit should be allowed")
+		}
+		allowed, retryAfter := b.take()
+		if allowed {
+			t.Fatal("third request beyond limit should be blocked")
+		}
+		if retryAfter <= 0 {
+			t.Errorf("retryAfter should be positive when blocked, got %v", retryAfter)
+		}
+	})
+
+	t.Run("refills over time", func(t *testing.T) {
+		b := newTokenBucket(rateLimitRule{limit: 1, period: time.Millisecond})
+		if allowed, _ := b.take(); !allowed {
+			t.Fatal("first request should be allowed")
+		}
+		time.Sleep(5 * time.Millisecond)
+		if allowed, _ := b.take(); !allowed {
+			t.Fatal("request after refill window should be allowed again")
+		}
+	})
+}
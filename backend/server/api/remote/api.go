@@ -0,0 +1,197 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remote lets external plugin processes register themselves with a
+// running devlake server instead of being compiled into the binary, via
+// POST /remote-plugins/register. A registered plugin gets a CRUD-style
+// connection API, its OpenAPI spec served under /plugin-swagger/<name>
+// (see api.go's servePluginSwagger), and its PluginApi calls dispatched
+// over RPC (see rpc.go).
+package remote
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/apache/incubator-devlake/server/api/shared"
+)
+
+// RegisterRoutes mounts the runtime plugin registration subsystem. It should
+// be called once from SetupApiServer, before RegisterRouter.
+//
+// Registration itself lives under /remote-plugins rather than /plugins -
+// the core RegisterRouter (called right after this) mounts compiled-in
+// plugin routes as /plugins/:pluginName/..., and gin forbids a static
+// sibling (/plugins/register) at the same node as that wildcard, as well as
+// a second param name at the same position. The connection CRUD routes
+// reuse the :pluginName param for the same reason.
+func RegisterRoutes(router *gin.Engine) {
+	router.POST("/remote-plugins/register", postRegister)
+	router.POST("/remote-plugins/:pluginName/heartbeat", postHeartbeat)
+
+	connections := router.Group("/plugins/:pluginName/connections")
+	connections.GET("", listConnections)
+	connections.POST("", createConnection)
+	connections.GET("/:connectionId", getConnection)
+	connections.PATCH("/:connectionId", updateConnection)
+	connections.DELETE("/:connectionId", deleteConnection)
+	connections.POST("/:connectionId/collect", runCollector)
+}
+
+func postRegister(ctx *gin.Context) {
+	var req RegistrationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		shared.ApiOutputError(ctx, errors.BadInput.Wrap(err, "error decoding remote plugin registration request"))
+		return
+	}
+	p, err := Register(&req)
+	if err != nil {
+		shared.ApiOutputError(ctx, err)
+		return
+	}
+	shared.ApiOutputSuccess(ctx, p, http.StatusOK)
+}
+
+func postHeartbeat(ctx *gin.Context) {
+	name := ctx.Param("pluginName")
+	if !touch(name) {
+		shared.ApiOutputError(ctx, errors.NotFound.New("remote plugin is not registered"))
+		return
+	}
+	shared.ApiOutputSuccess(ctx, nil, http.StatusOK)
+}
+
+// withPlugin resolves the :pluginName route param to a registered remote
+// plugin, or writes a 404 and returns ok=false.
+func withPlugin(ctx *gin.Context) (*Plugin, bool) {
+	p, ok := Get(ctx.Param("pluginName"))
+	if !ok {
+		shared.ApiOutputError(ctx, errors.NotFound.New("remote plugin is not registered"))
+		return nil, false
+	}
+	return p, true
+}
+
+func listConnections(ctx *gin.Context) {
+	p, ok := withPlugin(ctx)
+	if !ok {
+		return
+	}
+	dispatchCrud(ctx, p, "connections/list", nil)
+}
+
+func createConnection(ctx *gin.Context) {
+	p, ok := withPlugin(ctx)
+	if !ok {
+		return
+	}
+	body, err := ctx.GetRawData()
+	if err != nil {
+		shared.ApiOutputError(ctx, errors.BadInput.Wrap(err, "error reading connection payload"))
+		return
+	}
+	dispatchCrud(ctx, p, "connections/create", body)
+}
+
+func getConnection(ctx *gin.Context) {
+	p, ok := withPlugin(ctx)
+	if !ok {
+		return
+	}
+	dispatchCrud(ctx, p, "connections/get/"+ctx.Param("connectionId"), nil)
+}
+
+func updateConnection(ctx *gin.Context) {
+	p, ok := withPlugin(ctx)
+	if !ok {
+		return
+	}
+	body, err := ctx.GetRawData()
+	if err != nil {
+		shared.ApiOutputError(ctx, errors.BadInput.Wrap(err, "error reading connection payload"))
+		return
+	}
+	dispatchCrud(ctx, p, "connections/update/"+ctx.Param("connectionId"), body)
+}
+
+func deleteConnection(ctx *gin.Context) {
+	p, ok := withPlugin(ctx)
+	if !ok {
+		return
+	}
+	dispatchCrud(ctx, p, "connections/delete/"+ctx.Param("connectionId"), nil)
+}
+
+// runCollector triggers a collection run on the remote plugin and streams
+// each progress chunk it emits straight back to the caller as
+// newline-delimited JSON, rather than blocking until the whole run
+// finishes. This is the one place httpClient.Stream is actually dispatched
+// from; dispatchCrud below uses Call instead since connection CRUD is
+// request/response.
+func runCollector(ctx *gin.Context) {
+	p, ok := withPlugin(ctx)
+	if !ok {
+		return
+	}
+	body, err := ctx.GetRawData()
+	if err != nil {
+		shared.ApiOutputError(ctx, errors.BadInput.Wrap(err, "error reading collector payload"))
+		return
+	}
+	c, clientErr := newClient(p)
+	if clientErr != nil {
+		shared.ApiOutputError(ctx, clientErr)
+		return
+	}
+
+	ctx.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	streamErr := c.Stream(ctx.Request.Context(), "connections/"+ctx.Param("connectionId")+"/collect", body, func(chunk []byte) errors.Error {
+		if _, writeErr := ctx.Writer.Write(append(chunk, '\n')); writeErr != nil {
+			return errors.Default.Wrap(writeErr, "error writing collector stream chunk")
+		}
+		if flusher, ok := ctx.Writer.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if streamErr != nil {
+		// The 200 and any prior chunks are already on the wire, so all we
+		// can do at this point is log - there's no clean way to turn a
+		// partially-streamed response into an error envelope.
+		logruslog.Global.Error(streamErr, "error streaming remote plugin collector run")
+	}
+}
+
+// dispatchCrud forwards a connection CRUD call to the remote plugin over
+// the request/response RPC client and relays its response back verbatim.
+func dispatchCrud(ctx *gin.Context, p *Plugin, method string, body []byte) {
+	c, err := newClient(p)
+	if err != nil {
+		shared.ApiOutputError(ctx, err)
+		return
+	}
+	respBody, err := c.Call(ctx.Request.Context(), method, body)
+	if err != nil {
+		shared.ApiOutputError(ctx, err)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/json", respBody)
+}
@@ -0,0 +1,61 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"time"
+
+	"github.com/apache/incubator-devlake/impls/logruslog"
+)
+
+// heartbeatTimeout is how long a remote plugin may go without a heartbeat
+// before it's considered dead and unregistered.
+const heartbeatTimeout = 90 * time.Second
+
+// heartbeatInterval is how often the liveness sweep runs.
+const heartbeatInterval = 30 * time.Second
+
+// StartLivenessProbe periodically drops remote plugins that haven't sent a
+// heartbeat within heartbeatTimeout. It runs for the lifetime of the
+// process, so it should be started once from Init via a background
+// goroutine.
+func StartLivenessProbe(ctx context.Context) {
+	ticker := time.NewTicker(heartbeatInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sweepDeadPlugins()
+			}
+		}
+	}()
+}
+
+func sweepDeadPlugins() {
+	now := time.Now()
+	for name, p := range AllPlugins() {
+		if now.Sub(p.LastSeenAt) > heartbeatTimeout {
+			logruslog.Global.Warnf("remote plugin %s missed its heartbeat, unregistering", name)
+			Unregister(name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import "time"
+
+// Transport describes how the devlake server should reach a remote plugin
+// process. Only HttpEndpoint is used to reach the plugin (see newClient);
+// Cmd and GrpcEndpoint are accepted and persisted but never used to
+// connect.
+type Transport struct {
+	// Cmd is stored with the registration; the server does not spawn it.
+	Cmd string `json:"cmd,omitempty"`
+	// GrpcEndpoint is stored with the registration; no gRPC client exists,
+	// so a plugin with only this set cannot be called.
+	GrpcEndpoint string `json:"grpcEndpoint,omitempty"`
+	// HttpEndpoint is used for both request/response and streaming
+	// PluginApi calls.
+	HttpEndpoint string `json:"httpEndpoint,omitempty"`
+}
+
+// ModelColumn describes a single column of a runtime-managed GORM model.
+type ModelColumn struct {
+	Name string `json:"name" binding:"required"`
+	Type string `json:"type" binding:"required"`
+}
+
+// ModelSchema describes a GORM model a remote plugin wants the core
+// migration runner to create and manage on its behalf.
+type ModelSchema struct {
+	TableName string        `json:"tableName" binding:"required"`
+	Columns   []ModelColumn `json:"columns" binding:"required"`
+}
+
+// RegistrationRequest is the payload accepted by POST /remote-plugins/register.
+type RegistrationRequest struct {
+	Name        string        `json:"name" binding:"required"`
+	Transport   Transport     `json:"transport" binding:"required"`
+	OpenApiSpec string        `json:"openApiSpec"`
+	Models      []ModelSchema `json:"models"`
+}
+
+// Plugin is a registered remote plugin, tracked alongside the in-process
+// plugin.AllPlugins() registry for the lifetime of the devlake process.
+type Plugin struct {
+	Name         string        `json:"name"`
+	Transport    Transport     `json:"transport"`
+	OpenApiSpec  string        `json:"openApiSpec"`
+	Models       []ModelSchema `json:"models"`
+	RegisteredAt time.Time     `json:"registeredAt"`
+	LastSeenAt   time.Time     `json:"lastSeenAt"`
+}
+
+// pluginRegistration is the GORM-backed record used to persist remote
+// plugin registrations so they survive a devlake restart.
+type pluginRegistration struct {
+	Name         string `gorm:"primaryKey"`
+	Transport    string `gorm:"type:text"`
+	OpenApiSpec  string `gorm:"type:longtext"`
+	Models       string `gorm:"type:longtext"`
+	RegisteredAt time.Time
+	LastSeenAt   time.Time
+}
+
+func (pluginRegistration) TableName() string {
+	return "_devlake_remote_plugins"
+}
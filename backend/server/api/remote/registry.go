@@ -0,0 +1,191 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// registry is the parallel, process-lifetime map of remote (out-of-process)
+// plugins, queried by SetupApiServer alongside plugin.AllPlugins().
+type registry struct {
+	sync.RWMutex
+	plugins  map[string]*Plugin
+	basicRes context.BasicRes
+}
+
+var global = &registry{plugins: map[string]*Plugin{}}
+
+// Init wires the registry to the application's BasicRes so registrations
+// can be persisted and reloaded across restarts.
+func Init(basicRes context.BasicRes) errors.Error {
+	global.basicRes = basicRes
+	if err := basicRes.GetDal().AutoMigrate(&pluginRegistration{}); err != nil {
+		return errors.Default.Wrap(err, "error migrating remote plugin registration table")
+	}
+	return global.restore()
+}
+
+// AllPlugins returns a snapshot of every currently registered remote plugin,
+// keyed by name.
+func AllPlugins() map[string]*Plugin {
+	global.RLock()
+	defer global.RUnlock()
+	out := make(map[string]*Plugin, len(global.plugins))
+	for name, p := range global.plugins {
+		copied := *p
+		out[name] = &copied
+	}
+	return out
+}
+
+// Get returns the registered remote plugin by name, if any.
+func Get(name string) (*Plugin, bool) {
+	global.RLock()
+	defer global.RUnlock()
+	p, ok := global.plugins[name]
+	return p, ok
+}
+
+// Register adds or replaces a remote plugin and persists the registration.
+func Register(req *RegistrationRequest) (*Plugin, errors.Error) {
+	if err := global.migrateModels(req.Models); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p := &Plugin{
+		Name:         req.Name,
+		Transport:    req.Transport,
+		OpenApiSpec:  req.OpenApiSpec,
+		Models:       req.Models,
+		RegisteredAt: now,
+		LastSeenAt:   now,
+	}
+	global.Lock()
+	global.plugins[p.Name] = p
+	global.Unlock()
+	if err := global.persist(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Unregister drops a remote plugin, e.g. because its heartbeat expired.
+func Unregister(name string) {
+	global.Lock()
+	delete(global.plugins, name)
+	global.Unlock()
+	if global.basicRes != nil {
+		_ = global.basicRes.GetDal().Delete(&pluginRegistration{}, dal.Where("name = ?", name))
+	}
+}
+
+// touch refreshes the last-seen timestamp of a heartbeating plugin.
+func touch(name string) bool {
+	global.Lock()
+	defer global.Unlock()
+	p, ok := global.plugins[name]
+	if !ok {
+		return false
+	}
+	p.LastSeenAt = time.Now()
+	return true
+}
+
+// migrateModels creates one table per ModelSchema a remote plugin declares
+// at registration time, running CREATE TABLE IF NOT EXISTS directly, so
+// Models are actually created instead of only being persisted alongside the
+// registration record. Existing tables are not altered, and schemas with
+// no columns are skipped.
+func (r *registry) migrateModels(models []ModelSchema) errors.Error {
+	if r.basicRes == nil {
+		return nil
+	}
+	for _, model := range models {
+		if len(model.Columns) == 0 {
+			continue
+		}
+		columns := make([]string, 0, len(model.Columns))
+		for _, col := range model.Columns {
+			columns = append(columns, fmt.Sprintf("%s %s", col.Name, col.Type))
+		}
+		ddl := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", model.TableName, strings.Join(columns, ", "))
+		if err := r.basicRes.GetDal().Exec(ddl); err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("error migrating remote plugin model %s", model.TableName))
+		}
+	}
+	return nil
+}
+
+func (r *registry) persist(p *Plugin) errors.Error {
+	if r.basicRes == nil {
+		return nil
+	}
+	transport, err := json.Marshal(p.Transport)
+	if err != nil {
+		return errors.Default.Wrap(err, "error marshaling remote plugin transport")
+	}
+	models, err := json.Marshal(p.Models)
+	if err != nil {
+		return errors.Default.Wrap(err, "error marshaling remote plugin models")
+	}
+	rec := &pluginRegistration{
+		Name:         p.Name,
+		Transport:    string(transport),
+		OpenApiSpec:  p.OpenApiSpec,
+		Models:       string(models),
+		RegisteredAt: p.RegisteredAt,
+		LastSeenAt:   p.LastSeenAt,
+	}
+	if dbErr := r.basicRes.GetDal().CreateOrUpdate(rec); dbErr != nil {
+		return errors.Default.Wrap(dbErr, "error persisting remote plugin registration")
+	}
+	return nil
+}
+
+func (r *registry) restore() errors.Error {
+	var recs []pluginRegistration
+	if err := r.basicRes.GetDal().All(&recs); err != nil {
+		return errors.Default.Wrap(err, "error loading persisted remote plugin registrations")
+	}
+	r.Lock()
+	defer r.Unlock()
+	for _, rec := range recs {
+		var transport Transport
+		var models []ModelSchema
+		_ = json.Unmarshal([]byte(rec.Transport), &transport)
+		_ = json.Unmarshal([]byte(rec.Models), &models)
+		r.plugins[rec.Name] = &Plugin{
+			Name:         rec.Name,
+			Transport:    transport,
+			OpenApiSpec:  rec.OpenApiSpec,
+			Models:       models,
+			RegisteredAt: rec.RegisteredAt,
+			LastSeenAt:   rec.LastSeenAt,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// client is the RPC abstraction used to dispatch calls into a remote
+// plugin's PluginApi methods. CRUD-style calls go through Call; collectors
+// and other long-running work go through Stream so the caller can consume
+// partial progress instead of blocking for the whole operation.
+type client interface {
+	// Call performs a single request/response RPC against the remote
+	// plugin and returns the raw JSON response body.
+	Call(ctx context.Context, method string, body []byte) ([]byte, errors.Error)
+	// Stream performs a long-running RPC, invoking onChunk for every
+	// message the remote plugin emits until it closes the stream.
+	Stream(ctx context.Context, method string, body []byte, onChunk func([]byte) errors.Error) errors.Error
+}
+
+// newClient picks a transport for the given plugin. Only HttpEndpoint is
+// supported, for both Call and Stream; a plugin with only GrpcEndpoint or
+// only Cmd set gets an error instead of a client.
+func newClient(p *Plugin) (client, errors.Error) {
+	switch {
+	case p.Transport.HttpEndpoint != "":
+		return &httpClient{baseURL: p.Transport.HttpEndpoint}, nil
+	case p.Transport.GrpcEndpoint != "":
+		return nil, errors.Default.New("gRPC transport is not yet implemented, use httpEndpoint")
+	default:
+		return nil, errors.Default.New("remote plugin has no reachable transport configured")
+	}
+}
+
+type httpClient struct {
+	baseURL string
+}
+
+func (c *httpClient) Call(ctx context.Context, method string, body []byte) ([]byte, errors.Error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error building remote plugin request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error calling remote plugin")
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error reading remote plugin response")
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, errors.HttpStatus(resp.StatusCode).New(string(respBody))
+	}
+	return respBody, nil
+}
+
+// Stream sends a single request and then treats the response body as a
+// stream of newline-delimited JSON chunks, which is the framing remote
+// collector plugins are expected to use for progress updates.
+func (c *httpClient) Stream(ctx context.Context, method string, body []byte, onChunk func([]byte) errors.Error) errors.Error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return errors.Default.Wrap(err, "error building remote plugin stream request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Default.Wrap(err, "error calling remote plugin")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		respBody, _ := io.ReadAll(resp.Body)
+		return errors.HttpStatus(resp.StatusCode).New(string(respBody))
+	}
+	decoder := json.NewDecoder(resp.Body)
+	for decoder.More() {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return errors.Default.Wrap(err, "error decoding remote plugin stream chunk")
+		}
+		if err := onChunk(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shared holds the small set of response helpers every api handler
+// depends on, so the response envelope only needs to change in one place.
+package shared
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// ErrorResponse is the envelope returned for every non-2xx response. It is
+// deliberately flat so config-ui can render userMessage without knowing
+// anything about the error's underlying code, and causes/stack are only
+// populated when there's something useful to show.
+type ErrorResponse struct {
+	// Code is a machine-readable identifier, e.g. "MIGRATION_REQUIRED" or
+	// "PLUGIN_NOT_FOUND", for clients that want to branch on error kind
+	// instead of parsing messages.
+	Code string `json:"code"`
+	// Message is the full internal detail message, including the wrapped
+	// cause chain, intended for logs rather than end users.
+	Message string `json:"message"`
+	// UserMessage is safe to surface directly in config-ui.
+	UserMessage string `json:"userMessage"`
+	// Causes lists the wrapped cause chain, innermost cause last.
+	Causes []string `json:"causes,omitempty"`
+	// Stack is only populated when ENABLE_STACKTRACE is on, so production
+	// deployments don't leak internals in API responses.
+	Stack string `json:"stack,omitempty"`
+}
+
+// ApiOutputError writes a structured ErrorResponse for err, deriving the
+// HTTP status from the error's HTTP status hint (defaulting to 500). Stack
+// is only ever non-empty when errors.EnableStacktrace(true) was set at
+// startup, since that's what gates capturing it in the first place - so
+// there's nothing to strip here when the flag is off.
+func ApiOutputError(ctx *gin.Context, err errors.Error) {
+	if err == nil {
+		return
+	}
+	status := errors.HttpStatusOf(err)
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	ctx.JSON(status, ErrorResponse{
+		Code:        errors.CodeOf(err),
+		Message:     err.Error(),
+		UserMessage: errors.UserMessageOf(err),
+		Causes:      errors.CausesOf(err),
+		Stack:       errors.StackOf(err),
+	})
+}
+
+// ApiOutputSuccess writes body as JSON with the given HTTP status. It's the
+// success-path counterpart to ApiOutputError, kept here so handlers never
+// need to reach for ctx.JSON directly.
+func ApiOutputSuccess(ctx *gin.Context, body interface{}, status int) {
+	if body == nil {
+		ctx.Status(status)
+		return
+	}
+	ctx.JSON(status, body)
+}
@@ -0,0 +1,249 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/server/api/remote"
+	"github.com/apache/incubator-devlake/server/api/shared"
+)
+
+// aggregatedSpecCache holds the last merged spec so /swagger/aggregated/doc.json
+// doesn't re-parse every plugin's spec on every request. It's rebuilt lazily
+// on first access after startup, or after a POST to the reload endpoint.
+var aggregatedSpecCache struct {
+	sync.RWMutex
+	doc map[string]interface{}
+}
+
+// registerAggregatedOpenApiSpec exposes a single Swagger UI and doc.json
+// that merges the core spec with every plugin's (compiled-in or
+// runtime-registered) spec, namespaced under /plugins/<name>/..., so
+// client-code generators only need to point at one URL.
+//
+// This deliberately deviates from the /swagger/aggregated/doc.json and
+// /swagger/aggregated/*any paths originally requested: the baseline
+// /swagger/*any catch-all is already registered, and gin panics at startup
+// on a static sibling ("aggregated") at the same node as an existing
+// wildcard. It lives under its own /swagger-aggregated prefix instead.
+func registerAggregatedOpenApiSpec(router *gin.Engine) {
+	router.GET("/swagger-aggregated/doc.json", func(ctx *gin.Context) {
+		doc, err := getOrBuildAggregatedSpec()
+		if err != nil {
+			shared.ApiOutputError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, doc)
+	})
+
+	router.POST("/swagger-aggregated/reload", func(ctx *gin.Context) {
+		invalidateAggregatedSpec()
+		shared.ApiOutputSuccess(ctx, nil, http.StatusOK)
+	})
+
+	router.GET(
+		"/swagger-aggregated/ui/*any",
+		ginSwagger.CustomWrapHandler(
+			&ginSwagger.Config{
+				URL:          "/swagger-aggregated/doc.json",
+				DocExpansion: "list",
+				InstanceName: "aggregated",
+				Title:        "DevLake API (aggregated)",
+				DeepLinking:  true,
+			},
+			swaggerFiles.Handler,
+		),
+	)
+}
+
+func invalidateAggregatedSpec() {
+	aggregatedSpecCache.Lock()
+	defer aggregatedSpecCache.Unlock()
+	aggregatedSpecCache.doc = nil
+}
+
+func getOrBuildAggregatedSpec() (map[string]interface{}, errors.Error) {
+	aggregatedSpecCache.RLock()
+	if aggregatedSpecCache.doc != nil {
+		defer aggregatedSpecCache.RUnlock()
+		return aggregatedSpecCache.doc, nil
+	}
+	aggregatedSpecCache.RUnlock()
+
+	doc, err := buildAggregatedSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	aggregatedSpecCache.Lock()
+	aggregatedSpecCache.doc = doc
+	aggregatedSpecCache.Unlock()
+	return doc, nil
+}
+
+// buildAggregatedSpec walks every plugin.AllPlugins() entry implementing
+// PluginOpenApiSpec plus every runtime-registered remote plugin, and merges
+// their paths/definitions/tags into the core spec. Collisions on
+// definition names are resolved by prefixing with the plugin name, since
+// two plugins are free to both define e.g. a "Connection" model.
+func buildAggregatedSpec() (map[string]interface{}, errors.Error) {
+	coreDoc, docErr := swag.ReadDoc(swag.Name)
+	if docErr != nil {
+		return nil, errors.Default.Wrap(docErr, "error reading core swagger spec")
+	}
+	core, err := decodeSpec(coreDoc)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error decoding core swagger spec")
+	}
+
+	paths, _ := core["paths"].(map[string]interface{})
+	if paths == nil {
+		paths = map[string]interface{}{}
+	}
+	definitions, _ := core["definitions"].(map[string]interface{})
+	if definitions == nil {
+		definitions = map[string]interface{}{}
+	}
+	tags, _ := core["tags"].([]interface{})
+
+	specs := map[string]string{}
+	for name, pluginMeta := range plugin.AllPlugins() {
+		if s, ok := pluginMeta.(plugin.PluginOpenApiSpec); ok {
+			specs[name] = s.OpenApiSpec()
+		}
+	}
+	for name, remotePlugin := range remote.AllPlugins() {
+		if remotePlugin.OpenApiSpec != "" {
+			specs[name] = remotePlugin.OpenApiSpec
+		}
+	}
+
+	for name, raw := range specs {
+		pluginSpec, err := decodeSpec(raw)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("error decoding swagger spec for plugin %s", name))
+		}
+		// Renames must be decided and applied to every $ref in this
+		// plugin's spec before its paths/definitions are merged in,
+		// otherwise a renamed definition's refs keep pointing at whatever
+		// happened to already occupy that name (core's, or an earlier
+		// plugin's).
+		renames := definitionRenames(definitions, pluginSpec, name)
+		if len(renames) > 0 {
+			rewriteDefinitionRefs(pluginSpec, renames)
+		}
+		mergePaths(paths, pluginSpec, name)
+		mergeDefinitions(definitions, pluginSpec, renames)
+		if pluginTags, ok := pluginSpec["tags"].([]interface{}); ok {
+			tags = append(tags, pluginTags...)
+		}
+	}
+
+	core["paths"] = paths
+	core["definitions"] = definitions
+	if len(tags) > 0 {
+		core["tags"] = tags
+	}
+	return core, nil
+}
+
+func decodeSpec(raw string) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	if raw == "" {
+		return doc, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// mergePaths namespaces every path of a plugin's spec under
+// /plugins/<name>/... before merging it into the aggregated path map.
+func mergePaths(dst map[string]interface{}, pluginSpec map[string]interface{}, name string) {
+	pluginPaths, _ := pluginSpec["paths"].(map[string]interface{})
+	for path, item := range pluginPaths {
+		dst[fmt.Sprintf("/plugins/%s%s", name, path)] = item
+	}
+}
+
+// definitionRenames decides, for a single plugin's spec, which definition
+// names collide with one already in dst and so must be prefixed with the
+// plugin name. It only decides the renames; mergeDefinitions applies them,
+// and rewriteDefinitionRefs fixes up every $ref pointing at the old name.
+func definitionRenames(dst map[string]interface{}, pluginSpec map[string]interface{}, name string) map[string]string {
+	pluginDefinitions, _ := pluginSpec["definitions"].(map[string]interface{})
+	renames := map[string]string{}
+	for defName := range pluginDefinitions {
+		if _, collides := dst[defName]; collides {
+			renames[defName] = fmt.Sprintf("%s_%s", name, defName)
+		}
+	}
+	return renames
+}
+
+// mergeDefinitions merges a plugin's model definitions into dst, keyed by
+// name unless renames says otherwise.
+func mergeDefinitions(dst map[string]interface{}, pluginSpec map[string]interface{}, renames map[string]string) {
+	pluginDefinitions, _ := pluginSpec["definitions"].(map[string]interface{})
+	for defName, def := range pluginDefinitions {
+		key := defName
+		if renamed, ok := renames[defName]; ok {
+			key = renamed
+		}
+		dst[key] = def
+	}
+}
+
+// rewriteDefinitionRefs walks every $ref in a plugin's spec (its paths and
+// its own definitions, which may reference each other) and repoints any
+// "#/definitions/<old>" at "#/definitions/<renames[old]>", so a renamed
+// definition doesn't leave behind refs that resolve to whatever already
+// occupied that name.
+func rewriteDefinitionRefs(node interface{}, renames map[string]string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if key == "$ref" {
+				if ref, ok := val.(string); ok {
+					if renamed, ok := renames[strings.TrimPrefix(ref, "#/definitions/")]; ok && strings.HasPrefix(ref, "#/definitions/") {
+						v[key] = "#/definitions/" + renamed
+					}
+				}
+				continue
+			}
+			rewriteDefinitionRefs(val, renames)
+		}
+	case []interface{}:
+		for _, item := range v {
+			rewriteDefinitionRefs(item, renames)
+		}
+	}
+}
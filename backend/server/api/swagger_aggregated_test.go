@@ -0,0 +1,115 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePaths(t *testing.T) {
+	dst := map[string]interface{}{}
+	pluginSpec := map[string]interface{}{
+		"paths": map[string]interface{}{
+			"/connections": map[string]interface{}{"get": "list"},
+		},
+	}
+	mergePaths(dst, pluginSpec, "github")
+	want := map[string]interface{}{
+		"/plugins/github/connections": map[string]interface{}{"get": "list"},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("mergePaths() = %v, want %v", dst, want)
+	}
+}
+
+func TestDefinitionRenamesAndMerge(t *testing.T) {
+	dst := map[string]interface{}{
+		"Connection": map[string]interface{}{"core": true},
+	}
+	pluginSpec := map[string]interface{}{
+		"definitions": map[string]interface{}{
+			"Connection": map[string]interface{}{"plugin": "github"},
+			"Issue":      map[string]interface{}{"plugin": "github"},
+		},
+	}
+
+	renames := definitionRenames(dst, pluginSpec, "github")
+	if len(renames) != 1 || renames["Connection"] != "github_Connection" {
+		t.Fatalf("definitionRenames() = %v, want only Connection renamed to github_Connection", renames)
+	}
+
+	mergeDefinitions(dst, pluginSpec, renames)
+	if _, ok := dst["github_Connection"]; !ok {
+		t.Error("colliding definition should be merged under its renamed key")
+	}
+	if _, ok := dst["Issue"]; !ok {
+		t.Error("non-colliding definition should be merged under its original key")
+	}
+	if core, _ := dst["Connection"].(map[string]interface{}); core["core"] != true {
+		t.Error("core's original Connection definition must not be overwritten by the renamed plugin one")
+	}
+}
+
+func TestRewriteDefinitionRefs(t *testing.T) {
+	renames := map[string]string{"Connection": "github_Connection"}
+	spec := map[string]interface{}{
+		"paths": map[string]interface{}{
+			"/connections": map[string]interface{}{
+				"get": map[string]interface{}{
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"schema": map[string]interface{}{"$ref": "#/definitions/Connection"},
+						},
+					},
+				},
+			},
+		},
+		"definitions": map[string]interface{}{
+			"Issue": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"connection": map[string]interface{}{"$ref": "#/definitions/Connection"},
+				},
+			},
+			"Other": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"unrelated": map[string]interface{}{"$ref": "#/definitions/Unrelated"},
+				},
+			},
+		},
+	}
+
+	rewriteDefinitionRefs(spec, renames)
+
+	paths := spec["paths"].(map[string]interface{})
+	got := paths["/connections"].(map[string]interface{})["get"].(map[string]interface{})["responses"].(map[string]interface{})["200"].(map[string]interface{})["schema"].(map[string]interface{})["$ref"]
+	if got != "#/definitions/github_Connection" {
+		t.Errorf("ref in paths should be rewritten, got %v", got)
+	}
+
+	defs := spec["definitions"].(map[string]interface{})
+	issueRef := defs["Issue"].(map[string]interface{})["properties"].(map[string]interface{})["connection"].(map[string]interface{})["$ref"]
+	if issueRef != "#/definitions/github_Connection" {
+		t.Errorf("ref in another definition should also be rewritten, got %v", issueRef)
+	}
+
+	otherRef := defs["Other"].(map[string]interface{})["properties"].(map[string]interface{})["unrelated"].(map[string]interface{})["$ref"]
+	if otherRef != "#/definitions/Unrelated" {
+		t.Errorf("ref to a non-renamed definition must be left untouched, got %v", otherRef)
+	}
+}
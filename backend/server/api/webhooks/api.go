@@ -0,0 +1,170 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhooks lets plugins opt into a signed webhook receiver by
+// implementing plugin.PluginWebhookReceiver, instead of each plugin rolling
+// its own signature verification and replay protection.
+package webhooks
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/server/api/shared"
+)
+
+// defaultReplayTTLSeconds is used when WEBHOOK_REPLAY_TTL_SECONDS is unset
+// or invalid; an hour comfortably covers a provider's own retry window.
+const defaultReplayTTLSeconds = 3600
+
+// replayCapacity bounds memory use of the LRU regardless of delivery volume.
+const replayCapacity = 10000
+
+var (
+	basicRes context.BasicRes
+	recent   *seenIds
+)
+
+// RegisterRoutes mounts the webhook receiver subsystem. It should be called
+// once from SetupApiServer.
+func RegisterRoutes(router *gin.Engine, res context.BasicRes) {
+	basicRes = res
+	if err := basicRes.GetDal().AutoMigrate(&Delivery{}, &connectionSecret{}); err != nil {
+		panic(err)
+	}
+	recent = newSeenIds(replayCapacity, replayTTL(res))
+	router.POST("/webhooks/:plugin/:connectionId", receive)
+	router.GET("/webhooks/:plugin/:connectionId/deliveries", listDeliveries)
+}
+
+// replayTTL reads WEBHOOK_REPLAY_TTL_SECONDS, falling back to
+// defaultReplayTTLSeconds when unset or not a positive integer.
+func replayTTL(basicRes context.BasicRes) time.Duration {
+	seconds, err := strconv.Atoi(basicRes.GetConfig("WEBHOOK_REPLAY_TTL_SECONDS"))
+	if err != nil || seconds <= 0 {
+		seconds = defaultReplayTTLSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func receive(ctx *gin.Context) {
+	pluginName := ctx.Param("plugin")
+	connectionId := ctx.Param("connectionId")
+
+	receiver, err := lookupReceiver(pluginName)
+	if err != nil {
+		shared.ApiOutputError(ctx, err)
+		return
+	}
+
+	rawBody, ioErr := io.ReadAll(ctx.Request.Body)
+	if ioErr != nil {
+		shared.ApiOutputError(ctx, errors.BadInput.Wrap(ioErr, "error reading webhook body"))
+		return
+	}
+
+	deliveryId := firstNonEmptyHeader(ctx.Request.Header, "X-GitHub-Delivery", "X-Request-Id", "X-Event-Id")
+
+	secret, secretErr := GetConnectionSecret(pluginName, connectionId)
+	if secretErr != nil {
+		recordDelivery(pluginName, connectionId, deliveryId, DeliveryStatusRejected, secretErr.Error())
+		shared.ApiOutputError(ctx, errors.Unauthorized.Wrap(secretErr, "no webhook secret configured for this connection"))
+		return
+	}
+
+	// Signature must be checked before replay so an unauthenticated caller
+	// can't pre-seed an arbitrary delivery ID to get a legitimate, later
+	// delivery rejected as a duplicate.
+	if err := receiver.VerifySignature(ctx.Request.Header, rawBody, secret); err != nil {
+		recordDelivery(pluginName, connectionId, deliveryId, DeliveryStatusRejected, err.Error())
+		shared.ApiOutputError(ctx, errors.Unauthorized.Wrap(err, "webhook signature verification failed"))
+		return
+	}
+
+	if recent.checkAndRemember(deliveryId) {
+		recordDelivery(pluginName, connectionId, deliveryId, DeliveryStatusReplayed, "duplicate delivery id")
+		shared.ApiOutputError(ctx, errors.BadInput.New("duplicate delivery, already processed"))
+		return
+	}
+
+	if err := receiver.Handle(ctx.Request.Context(), rawBody); err != nil {
+		recordDelivery(pluginName, connectionId, deliveryId, DeliveryStatusError, err.Error())
+		shared.ApiOutputError(ctx, errors.Default.Wrap(err, "error handling webhook event"))
+		return
+	}
+
+	recordDelivery(pluginName, connectionId, deliveryId, DeliveryStatusAccepted, "")
+	shared.ApiOutputSuccess(ctx, nil, http.StatusOK)
+}
+
+func listDeliveries(ctx *gin.Context) {
+	var deliveries []Delivery
+	clauses := []dal.Clause{
+		dal.Where("plugin = ? AND connection_id = ?", ctx.Param("plugin"), ctx.Param("connectionId")),
+		dal.Orderby("received_at DESC"),
+		dal.Limit(100),
+	}
+	if err := basicRes.GetDal().All(&deliveries, clauses...); err != nil {
+		shared.ApiOutputError(ctx, errors.Default.Wrap(err, "error loading webhook deliveries"))
+		return
+	}
+	shared.ApiOutputSuccess(ctx, deliveries, http.StatusOK)
+}
+
+func lookupReceiver(pluginName string) (plugin.PluginWebhookReceiver, errors.Error) {
+	pluginMeta, ok := plugin.AllPlugins()[pluginName]
+	if !ok {
+		return nil, errors.NotFound.New("plugin not found: " + pluginName)
+	}
+	receiver, ok := pluginMeta.(plugin.PluginWebhookReceiver)
+	if !ok {
+		return nil, errors.BadInput.New("plugin does not accept webhooks: " + pluginName)
+	}
+	return receiver, nil
+}
+
+func recordDelivery(pluginName, connectionId, deliveryId string, status DeliveryStatus, detail string) {
+	if deliveryId == "" {
+		return
+	}
+	record := &Delivery{
+		Plugin:       pluginName,
+		ConnectionId: connectionId,
+		DeliveryId:   deliveryId,
+		Status:       status,
+		Detail:       detail,
+		ReceivedAt:   time.Now(),
+	}
+	_ = basicRes.GetDal().CreateOrUpdate(record)
+}
+
+func firstNonEmptyHeader(header http.Header, names ...string) string {
+	for _, name := range names {
+		if v := header.Get(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import "time"
+
+// DeliveryStatus is the outcome of a single webhook delivery attempt.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusAccepted DeliveryStatus = "accepted"
+	DeliveryStatusRejected DeliveryStatus = "rejected"
+	DeliveryStatusReplayed DeliveryStatus = "replayed"
+	DeliveryStatusError    DeliveryStatus = "error"
+)
+
+// Delivery is a record of one received webhook call, kept around so
+// operators can inspect recent activity the way CI systems expose
+// received-hook history.
+type Delivery struct {
+	Plugin       string         `json:"plugin" gorm:"primaryKey"`
+	ConnectionId string         `json:"connectionId" gorm:"primaryKey"`
+	DeliveryId   string         `json:"deliveryId" gorm:"primaryKey"`
+	Status       DeliveryStatus `json:"status"`
+	Detail       string         `json:"detail,omitempty"`
+	ReceivedAt   time.Time      `json:"receivedAt"`
+}
+
+func (Delivery) TableName() string {
+	return "_devlake_webhook_deliveries"
+}
+
+// connectionSecret is the GORM-backed, encrypted-at-rest shared secret used
+// to verify a connection's incoming webhook signatures.
+type connectionSecret struct {
+	Plugin          string `gorm:"primaryKey"`
+	ConnectionId    string `gorm:"primaryKey"`
+	EncryptedSecret string `gorm:"type:text"`
+}
+
+func (connectionSecret) TableName() string {
+	return "_devlake_webhook_secrets"
+}
@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// seenIds is a bounded LRU of recently-seen delivery IDs (e.g. GitHub's
+// X-GitHub-Delivery), used to reject replayed webhook deliveries. Entries
+// older than ttl are treated as not-seen even if they haven't been evicted
+// yet. Memory is bounded by capacity alone: expired entries stay in the
+// list until capacity pushes them out.
+type seenIds struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	index    map[string]*list.Element
+}
+
+type seenEntry struct {
+	id   string
+	seen time.Time
+}
+
+func newSeenIds(capacity int, ttl time.Duration) *seenIds {
+	return &seenIds{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		index:    map[string]*list.Element{},
+	}
+}
+
+// checkAndRemember returns true if id has already been seen within ttl,
+// and otherwise records it as seen and returns false.
+func (s *seenIds) checkAndRemember(id string) bool {
+	if id == "" {
+		// Plugins that don't send a delivery ID get no replay protection,
+		// rather than every un-identified delivery colliding on "".
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.index[id]; ok {
+		entry := el.Value.(*seenEntry)
+		if time.Since(entry.seen) <= s.ttl {
+			return true
+		}
+		entry.seen = time.Now()
+		s.order.MoveToFront(el)
+		return false
+	}
+
+	s.order.PushFront(&seenEntry{id: id, seen: time.Now()})
+	s.index[id] = s.order.Front()
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.index, oldest.Value.(*seenEntry).id)
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeenIdsCheckAndRemember(t *testing.T) {
+	t.Run("first sighting is not a replay", func(t *testing.T) {
+		s := newSeenIds(10, time.Hour)
+		if s.checkAndRemember("a") {
+			t.Fatal("first sighting of id should not be reported as a replay")
+		}
+	})
+
+	t.Run("second sighting within ttl is a replay", func(t *testing.T) {
+		s := newSeenIds(10, time.Hour)
+		s.checkAndRemember("a")
+		if !s.checkAndRemember("a") {
+			t.Fatal("repeated id within ttl should be reported as a replay")
+		}
+	})
+
+	t.Run("sighting after ttl is not a replay", func(t *testing.T) {
+		s := newSeenIds(10, time.Millisecond)
+		s.checkAndRemember("a")
+		time.Sleep(5 * time.Millisecond)
+		if s.checkAndRemember("a") {
+			t.Fatal("id seen again after ttl expired should not be reported as a replay")
+		}
+	})
+
+	t.Run("empty id never counts as seen", func(t *testing.T) {
+		s := newSeenIds(10, time.Hour)
+		if s.checkAndRemember("") {
+			t.Fatal("empty id should never be treated as a replay")
+		}
+		if s.checkAndRemember("") {
+			t.Fatal("empty id should never be treated as a replay, even repeated")
+		}
+	})
+
+	t.Run("capacity evicts the oldest id", func(t *testing.T) {
+		s := newSeenIds(2, time.Hour)
+		s.checkAndRemember("a")
+		s.checkAndRemember("b")
+		s.checkAndRemember("c") // evicts "a"
+		if s.checkAndRemember("a") {
+			t.Fatal("evicted id should not be reported as a replay")
+		}
+	})
+}
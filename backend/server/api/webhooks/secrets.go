@@ -0,0 +1,53 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// GetConnectionSecret returns the decrypted shared secret configured for a
+// connection's webhooks, for use with VerifyBuiltinSignature.
+func GetConnectionSecret(pluginName, connectionId string) (string, errors.Error) {
+	var rec connectionSecret
+	err := basicRes.GetDal().First(&rec, dal.Where("plugin = ? AND connection_id = ?", pluginName, connectionId))
+	if err != nil {
+		return "", errors.Default.Wrap(err, "error loading webhook secret")
+	}
+	secret, decErr := plugin.Decrypt(basicRes.GetConfig("ENCRYPTION_SECRET"), rec.EncryptedSecret)
+	if decErr != nil {
+		return "", errors.Default.Wrap(decErr, "error decrypting webhook secret")
+	}
+	return secret, nil
+}
+
+// SetConnectionSecret encrypts and persists the shared secret used to
+// verify a connection's incoming webhook signatures.
+func SetConnectionSecret(pluginName, connectionId, secret string) errors.Error {
+	ciphertext, err := plugin.Encrypt(basicRes.GetConfig("ENCRYPTION_SECRET"), secret)
+	if err != nil {
+		return errors.Default.Wrap(err, "error encrypting webhook secret")
+	}
+	rec := &connectionSecret{Plugin: pluginName, ConnectionId: connectionId, EncryptedSecret: ciphertext}
+	if err := basicRes.GetDal().CreateOrUpdate(rec); err != nil {
+		return errors.Default.Wrap(err, "error persisting webhook secret")
+	}
+	return nil
+}
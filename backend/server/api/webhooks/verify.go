@@ -0,0 +1,77 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // required for Bitbucket/legacy GitHub signature compatibility
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// VerifyBuiltinSignature checks the raw body against whichever well-known
+// signature header is present, falling back to a generic HMAC-SHA256 over
+// X-Signature for plugins that don't match a known provider. It's exported
+// so a PluginWebhookReceiver.VerifySignature implementation can delegate to
+// it instead of reimplementing HMAC comparison; plugins that need
+// something entirely custom are free to ignore it.
+func VerifyBuiltinSignature(headers http.Header, rawBody []byte, secret string) errors.Error {
+	switch {
+	case headers.Get("X-Hub-Signature-256") != "":
+		return verifyHmacSha256Prefixed(headers.Get("X-Hub-Signature-256"), "sha256=", rawBody, secret)
+	case headers.Get("X-Gitlab-Token") != "":
+		if !hmac.Equal([]byte(headers.Get("X-Gitlab-Token")), []byte(secret)) {
+			return errors.Unauthorized.New("gitlab webhook token mismatch")
+		}
+		return nil
+	case headers.Get("X-Hub-Signature") != "":
+		// Bitbucket and older GitHub webhooks sign with plain HMAC-SHA1;
+		// kept for compatibility even though SHA-256 is preferred.
+		return verifyHmacSha1Prefixed(headers.Get("X-Hub-Signature"), "sha1=", rawBody, secret)
+	case headers.Get("X-Signature") != "":
+		return verifyHmacSha256Prefixed(headers.Get("X-Signature"), "", rawBody, secret)
+	default:
+		return errors.BadInput.New("no recognized webhook signature header present")
+	}
+}
+
+func verifyHmacSha256Prefixed(header, prefix string, rawBody []byte, secret string) errors.Error {
+	expected := strings.TrimPrefix(header, prefix)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(rawBody)
+	computed := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(computed)) {
+		return errors.Unauthorized.New("webhook signature mismatch")
+	}
+	return nil
+}
+
+func verifyHmacSha1Prefixed(header, prefix string, rawBody []byte, secret string) errors.Error {
+	expected := strings.TrimPrefix(header, prefix)
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(rawBody)
+	computed := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(computed)) {
+		return errors.Unauthorized.New("webhook signature mismatch")
+	}
+	return nil
+}
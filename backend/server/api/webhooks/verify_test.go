@@ -0,0 +1,106 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // test fixture only, mirrors the legacy Bitbucket/GitHub signing scheme
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+)
+
+const testSecret = "s3cr3t"
+
+var testBody = []byte(`{"hello":"world"}`)
+
+func sha256Signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func sha1Signature(secret string, body []byte) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyBuiltinSignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers http.Header
+		wantErr bool
+	}{
+		{
+			name:    "valid github sha256 signature",
+			headers: http.Header{"X-Hub-Signature-256": {"sha256=" + sha256Signature(testSecret, testBody)}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid github sha256 signature",
+			headers: http.Header{"X-Hub-Signature-256": {"sha256=deadbeef"}},
+			wantErr: true,
+		},
+		{
+			name:    "valid gitlab token",
+			headers: http.Header{"X-Gitlab-Token": {testSecret}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid gitlab token",
+			headers: http.Header{"X-Gitlab-Token": {"wrong"}},
+			wantErr: true,
+		},
+		{
+			name:    "valid legacy sha1 signature",
+			headers: http.Header{"X-Hub-Signature": {"sha1=" + sha1Signature(testSecret, testBody)}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid legacy sha1 signature",
+			headers: http.Header{"X-Hub-Signature": {"sha1=deadbeef"}},
+			wantErr: true,
+		},
+		{
+			name:    "valid generic signature",
+			headers: http.Header{"X-Signature": {sha256Signature(testSecret, testBody)}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid generic signature",
+			headers: http.Header{"X-Signature": {"deadbeef"}},
+			wantErr: true,
+		},
+		{
+			name:    "no recognized header",
+			headers: http.Header{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyBuiltinSignature(tt.headers, testBody, testSecret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyBuiltinSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}